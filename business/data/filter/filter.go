@@ -0,0 +1,259 @@
+// Package filter provides support for describing the filtering of data.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/service/business/data/order"
+	"github.com/ardanlabs/service/business/sys/validate"
+)
+
+// Individual operators in the system.
+var (
+	EQ     = Op{"EQ"}
+	NEQ    = Op{"NEQ"}
+	LT     = Op{"LT"}
+	LTE    = Op{"LTE"}
+	GT     = Op{"GT"}
+	GTE    = Op{"GTE"}
+	LIKE   = Op{"LIKE"}
+	IN     = Op{"IN"}
+	ISNULL = Op{"ISNULL"}
+)
+
+// Set of known operators.
+var ops = map[string]Op{
+	EQ.name:     EQ,
+	NEQ.name:    NEQ,
+	LT.name:     LT,
+	LTE.name:    LTE,
+	GT.name:     GT,
+	GTE.name:    GTE,
+	LIKE.name:   LIKE,
+	IN.name:     IN,
+	ISNULL.name: ISNULL,
+}
+
+// Op defines a filter comparison operator.
+type Op struct {
+	name string
+}
+
+// parseOp converts a string to a type Op.
+func parseOp(value string) (Op, error) {
+	op, exists := ops[strings.ToUpper(value)]
+	if !exists {
+		return Op{}, errors.New("invalid operator")
+	}
+
+	return op, nil
+}
+
+// =============================================================================
+
+// FilterFunc renders a single condition into a parameterized sql fragment
+// starting at placeholder $paramIndex, letting a field customize its
+// rendering, for example a case-insensitive email match or a JSONB
+// containment check.
+type FilterFunc func(field order.Field, op Op, value string, paramIndex int) (sql string, args []any, err error)
+
+// =============================================================================
+
+// Field represents a field that can be filtered on. It wraps an order.Field
+// so the same Field value can be registered in both an order.FieldSet and a
+// filter.FieldSet, letting a handler declare a field's capabilities once.
+type Field struct {
+	field order.Field
+	fn    FilterFunc
+}
+
+// NewField constructs a new filterable field from an order.Field.
+func NewField(field order.Field) Field {
+	return Field{
+		field: field,
+	}
+}
+
+// AddFilterFunc registers a custom FilterFunc for this field, overriding the
+// default operator-to-sql rendering.
+func (f *Field) AddFilterFunc(fn FilterFunc) Field {
+	f.fn = fn
+
+	return *f
+}
+
+// =============================================================================
+
+// FieldSet maintains a set of fields that can be filtered on.
+type FieldSet struct {
+	fields map[string]Field
+}
+
+// NewFieldSet takes a set of fields to add to the set.
+func NewFieldSet(fields ...Field) FieldSet {
+	m := make(map[string]Field)
+
+	for _, field := range fields {
+		m[field.field.Name()] = field
+	}
+
+	return FieldSet{
+		fields: m,
+	}
+}
+
+// parseField takes a field by string and validates it belongs to the set.
+// Then returns that field in its proper type.
+func (fs FieldSet) parseField(name string) (Field, error) {
+	f, exists := fs.fields[name]
+	if !exists {
+		return Field{}, fmt.Errorf("field %q not found", name)
+	}
+
+	return f, nil
+}
+
+// =============================================================================
+
+// condition represents a single parsed "field:op:value" filter entry.
+type condition struct {
+	field Field
+	op    Op
+	value string
+}
+
+// Clause represents a set of conditions, combined with AND, ready to be
+// rendered into a parameterized WHERE fragment.
+type Clause struct {
+	conditions []condition
+}
+
+// SQL returns a parameterized WHERE fragment (without the WHERE keyword)
+// combining every condition with AND, along with its positional arguments,
+// starting at placeholder $paramIndex so it can be spliced alongside other
+// parameterized fragments in the same query.
+func (c Clause) SQL(paramIndex int) (string, []any, error) {
+	if len(c.conditions) == 0 {
+		return "", nil, nil
+	}
+
+	var clauses []string
+	var args []any
+
+	for _, cond := range c.conditions {
+		fn := cond.field.fn
+		if fn == nil {
+			fn = defaultFilterFunc
+		}
+
+		sql, condArgs, err := fn(cond.field.field, cond.op, cond.value, paramIndex+len(args))
+		if err != nil {
+			return "", nil, err
+		}
+
+		clauses = append(clauses, sql)
+		args = append(args, condArgs...)
+	}
+
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+// defaultFilterFunc renders the standard sql for each known Op.
+func defaultFilterFunc(field order.Field, op Op, value string, paramIndex int) (string, []any, error) {
+	storage := field.Storage()
+
+	switch op {
+	case EQ:
+		return fmt.Sprintf("%s = $%d", storage, paramIndex), []any{value}, nil
+
+	case NEQ:
+		return fmt.Sprintf("%s <> $%d", storage, paramIndex), []any{value}, nil
+
+	case LT:
+		return fmt.Sprintf("%s < $%d", storage, paramIndex), []any{value}, nil
+
+	case LTE:
+		return fmt.Sprintf("%s <= $%d", storage, paramIndex), []any{value}, nil
+
+	case GT:
+		return fmt.Sprintf("%s > $%d", storage, paramIndex), []any{value}, nil
+
+	case GTE:
+		return fmt.Sprintf("%s >= $%d", storage, paramIndex), []any{value}, nil
+
+	case LIKE:
+		return fmt.Sprintf("%s LIKE $%d", storage, paramIndex), []any{value}, nil
+
+	case IN:
+		values := strings.Split(value, "|")
+		placeholders := make([]string, len(values))
+		args := make([]any, len(values))
+		for i, v := range values {
+			placeholders[i] = "$" + strconv.Itoa(paramIndex+i)
+			args[i] = v
+		}
+
+		return fmt.Sprintf("%s IN (%s)", storage, strings.Join(placeholders, ", ")), args, nil
+
+	case ISNULL:
+		return storage + " IS NULL", nil, nil
+
+	default:
+		return "", nil, fmt.Errorf("unsupported operator %v", op)
+	}
+}
+
+// =============================================================================
+
+// Parse constructs a filter.Clause value by parsing a string in the form of
+// "field:op:value,field:op:value" from the request, for example
+// "age:gte:18,status:in:active|pending".
+func Parse(r *http.Request, filterFields FieldSet, defaultClause Clause) (Clause, error) {
+	v := r.URL.Query().Get("filter")
+
+	if v == "" {
+		return defaultClause, nil
+	}
+
+	parts := strings.Split(v, ",")
+
+	conditions := make([]condition, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.Trim(part, " ")
+		if part == "" {
+			continue
+		}
+
+		segs := strings.SplitN(part, ":", 3)
+		if len(segs) != 3 {
+			return Clause{}, validate.NewFieldsError(v, errors.New("parsing filter"))
+		}
+
+		field, err := filterFields.parseField(strings.Trim(segs[0], " "))
+		if err != nil {
+			return Clause{}, validate.NewFieldsError(v, errors.New("parsing fields"))
+		}
+
+		op, err := parseOp(strings.Trim(segs[1], " "))
+		if err != nil {
+			return Clause{}, validate.NewFieldsError(v, errors.New("parsing operator"))
+		}
+
+		conditions = append(conditions, condition{
+			field: field,
+			op:    op,
+			value: strings.Trim(segs[2], " "),
+		})
+	}
+
+	if len(conditions) == 0 {
+		return defaultClause, nil
+	}
+
+	return Clause{conditions: conditions}, nil
+}
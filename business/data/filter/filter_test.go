@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/business/data/order"
+)
+
+func newTestFields() FieldSet {
+	age := order.NewField("age")
+	age.AddStorageField("age")
+
+	status := order.NewField("status")
+	status.AddStorageField("status")
+
+	return NewFieldSet(NewField(age), NewField(status))
+}
+
+func TestParse(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?filter=age:gte:18,status:in:active|pending", nil)
+
+	clause, err := Parse(r, newTestFields(), Clause{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sql, args, err := clause.SQL(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := "age >= $1 AND status IN ($2, $3)"
+	if sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+
+	wantArgs := []any{"18", "active", "pending"}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("got %d args, want %d", len(args), len(wantArgs))
+	}
+	for i := range wantArgs {
+		if args[i] != wantArgs[i] {
+			t.Errorf("args[%d] = %v, want %v", i, args[i], wantArgs[i])
+		}
+	}
+}
+
+func TestClause_SQL_ParamIndexOffset(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?filter=age:eq:18", nil)
+
+	clause, err := Parse(r, newTestFields(), Clause{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	sql, _, err := clause.SQL(4)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := "age = $4"; sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+}
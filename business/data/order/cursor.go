@@ -0,0 +1,86 @@
+package order
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EncodeCursor produces an opaque, base64 page token for row, the tuple of
+// ordering column values from the last row of the current page, typically
+// []any{lastRow.LastName, lastRow.FirstName, lastRow.ID}.
+func EncodeCursor(row any) string {
+	b, err := json.Marshal(row)
+	if err != nil {
+		panic(fmt.Sprintf("order: EncodeCursor: %s", err))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor reverses EncodeCursor, validating the token decodes to
+// exactly as many values as by has fields. Numbers decode as json.Number
+// rather than float64 so large integer ids round-trip exactly.
+func DecodeCursor(s string, by ByList) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decoding cursor: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var values []any
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("decoding cursor: %w", err)
+	}
+
+	if len(values) != len(by) {
+		return nil, fmt.Errorf("cursor has %d values, expected %d for the given order", len(values), len(by))
+	}
+
+	return values, nil
+}
+
+// WhereAfter builds the parameterized predicate, starting at placeholder
+// $paramIndex, that a store layer splices into its base query to resume
+// paging after cursor, the ordering column values of the last row of the
+// previous page. It expands the lexicographic tuple comparison implied by
+// by into an equivalent OR of ANDs so each column's operator can be
+// inverted on its own when that column sorts DESC.
+//
+// cursor must have exactly len(by) values, in the same order as by; callers
+// get this guarantee for free by producing cursor with DecodeCursor(s, by).
+func WhereAfter(by ByList, cursor []any, paramIndex int) (string, []any) {
+	if len(cursor) != len(by) {
+		panic(fmt.Sprintf("order: WhereAfter: cursor has %d values, order has %d fields", len(cursor), len(by)))
+	}
+
+	var terms []string
+	var args []any
+
+	for i, leading := range by {
+		var parts []string
+
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = $%d", by[j].field.storage, paramIndex))
+			args = append(args, cursor[j])
+			paramIndex++
+		}
+
+		op := ">"
+		if leading.direction == DESC {
+			op = "<"
+		}
+
+		parts = append(parts, fmt.Sprintf("%s %s $%d", leading.field.storage, op, paramIndex))
+		args = append(args, cursor[i])
+		paramIndex++
+
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return strings.Join(terms, " OR "), args
+}
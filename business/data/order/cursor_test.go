@@ -0,0 +1,80 @@
+package order
+
+import "testing"
+
+func TestCursor_EncodeDecodeRoundTrip(t *testing.T) {
+	lastName := NewField("last_name")
+	lastName.AddStorageField("last_name")
+
+	id := NewField("id")
+	id.AddStorageField("id")
+
+	by := NewByList(NewBy(lastName, ASC), NewBy(id, DESC))
+
+	token := EncodeCursor([]any{"Smith", "9223372036854775807"})
+
+	values, err := DecodeCursor(token, by)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(values) != 2 {
+		t.Fatalf("got %d values, want 2", len(values))
+	}
+	if values[0] != "Smith" {
+		t.Errorf("values[0] = %v, want Smith", values[0])
+	}
+}
+
+func TestDecodeCursor_RejectsWrongArity(t *testing.T) {
+	lastName := NewField("last_name")
+	lastName.AddStorageField("last_name")
+
+	by := NewByList(NewBy(lastName, ASC))
+
+	token := EncodeCursor([]any{"Smith", "extra"})
+
+	if _, err := DecodeCursor(token, by); err == nil {
+		t.Fatal("expected an error for a cursor with too many values, got none")
+	}
+}
+
+func TestWhereAfter_InvertsDirectionPerColumn(t *testing.T) {
+	lastName := NewField("last_name")
+	lastName.AddStorageField("last_name")
+
+	id := NewField("id")
+	id.AddStorageField("id")
+
+	by := NewByList(NewBy(lastName, ASC), NewBy(id, DESC))
+
+	sql, args := WhereAfter(by, []any{"Smith", 7}, 1)
+
+	want := "(last_name > $1) OR (last_name = $2 AND id < $3)"
+	if sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+
+	wantArgs := []any{"Smith", "Smith", 7}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("got %d args, want %d", len(args), len(wantArgs))
+	}
+	for i := range wantArgs {
+		if args[i] != wantArgs[i] {
+			t.Errorf("args[%d] = %v, want %v", i, args[i], wantArgs[i])
+		}
+	}
+}
+
+func TestWhereAfter_ParamIndexOffset(t *testing.T) {
+	id := NewField("id")
+	id.AddStorageField("id")
+
+	by := NewByList(NewBy(id, ASC))
+
+	sql, _ := WhereAfter(by, []any{7}, 3)
+
+	if want := "(id > $3)"; sql != want {
+		t.Errorf("sql = %q, want %q", sql, want)
+	}
+}
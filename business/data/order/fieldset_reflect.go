@@ -0,0 +1,146 @@
+package order
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldSetCache holds the FieldSet built for a given model type so repeated
+// calls to FieldSetFromStruct for the same type don't pay the reflection
+// cost more than once.
+var fieldSetCache sync.Map // map[reflect.Type]FieldSet
+
+// FieldSetFromStruct builds a FieldSet from a model's `order` struct tags,
+// e.g. `order:"name=user_id,storage=users.id,sortable,default=DESC"`. A
+// `default_sorts` tag on any one field (e.g. `order:"default_sorts=user_id|name"`)
+// names the fields and order that make up the FieldSet's default ByList. The
+// result is cached per reflect.Type, and a bad tag panics rather than
+// returning an error since it's a programming error caught once at init.
+func FieldSetFromStruct(v any) FieldSet {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if cached, exists := fieldSetCache.Load(t); exists {
+		return cached.(FieldSet)
+	}
+
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("order: FieldSetFromStruct: %s is not a struct", t))
+	}
+
+	fields := make(map[string]Field)
+	defaultDirs := make(map[string]Direction)
+	var defaultSorts string
+
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("order")
+		if !ok {
+			continue
+		}
+
+		props := parseOrderTag(tag)
+
+		if ds, exists := props["default_sorts"]; exists {
+			if defaultSorts != "" {
+				panic(fmt.Sprintf("order: FieldSetFromStruct: %s: default_sorts specified more than once", t))
+			}
+
+			defaultSorts = ds
+		}
+
+		if _, sortable := props["sortable"]; !sortable {
+			continue
+		}
+
+		name := props["name"]
+		if name == "" {
+			panic(fmt.Sprintf("order: FieldSetFromStruct: %s.%s: order tag missing name", t, t.Field(i).Name))
+		}
+
+		if _, exists := fields[name]; exists {
+			panic(fmt.Sprintf("order: FieldSetFromStruct: %s: duplicate order field name %q", t, name))
+		}
+
+		field := NewField(name)
+		field.AddStorageField(props["storage"])
+		fields[name] = field
+
+		if dirName, exists := props["default"]; exists {
+			dir, exists := directions[dirName]
+			if !exists {
+				panic(fmt.Sprintf("order: FieldSetFromStruct: %s.%s: invalid default direction %q", t, t.Field(i).Name, dirName))
+			}
+
+			defaultDirs[name] = dir
+		}
+	}
+
+	fs := FieldSet{
+		fields: fields,
+	}
+
+	if defaultSorts != "" {
+		fs.defaultOrder = parseDefaultSorts(t, defaultSorts, fields, defaultDirs)
+	}
+
+	fieldSetCache.Store(t, fs)
+
+	return fs
+}
+
+// parseOrderTag splits an `order` struct tag into its comma separated
+// key=value properties. A bare word such as sortable is stored with an
+// empty value so its presence can still be checked.
+func parseOrderTag(tag string) map[string]string {
+	props := make(map[string]string)
+
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		k, v, found := strings.Cut(part, "=")
+		if !found {
+			props[k] = ""
+			continue
+		}
+
+		props[k] = v
+	}
+
+	return props
+}
+
+// parseDefaultSorts turns a "name|name" default_sorts value into a ByList,
+// pairing each name with the direction from its own default tag (ASC when
+// none was given) and validating every name exists in fields.
+func parseDefaultSorts(t reflect.Type, defaultSorts string, fields map[string]Field, defaultDirs map[string]Direction) ByList {
+	names := strings.Split(defaultSorts, "|")
+	byList := make(ByList, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		field, exists := fields[name]
+		if !exists {
+			panic(fmt.Sprintf("order: FieldSetFromStruct: %s: default_sorts references unknown field %q", t, name))
+		}
+
+		dir, exists := defaultDirs[name]
+		if !exists {
+			dir = ASC
+		}
+
+		byList = append(byList, NewBy(field, dir))
+	}
+
+	return byList
+}
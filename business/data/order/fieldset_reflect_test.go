@@ -0,0 +1,49 @@
+package order
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testUser struct {
+	ID        string `order:"name=user_id,storage=users.id,sortable,default=DESC"`
+	LastName  string `order:"name=last_name,storage=users.last_name,sortable,default=ASC"`
+	Password  string `order:"default_sorts=last_name|user_id"`
+	FirstName string `order:"name=first_name,storage=users.first_name,sortable"`
+}
+
+func TestFieldSetFromStruct(t *testing.T) {
+	fs := FieldSetFromStruct(testUser{})
+
+	field, err := fs.parseField("first_name")
+	if err != nil {
+		t.Fatalf("expected first_name to be registered: %s", err)
+	}
+
+	if field.storage != "users.first_name" {
+		t.Errorf("storage = %q, want %q", field.storage, "users.first_name")
+	}
+
+	_, clause, err := fs.DefaultOrder().SQL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := "users.last_name ASC, users.id DESC"
+	if clause != want {
+		t.Errorf("default order = %q, want %q", clause, want)
+	}
+}
+
+func TestFieldSetFromStruct_Cached(t *testing.T) {
+	first := FieldSetFromStruct(testUser{})
+	second := FieldSetFromStruct(testUser{})
+
+	if len(first.fields) != len(second.fields) {
+		t.Fatalf("expected the same cached FieldSet, got different field counts")
+	}
+
+	if _, cached := fieldSetCache.Load(reflect.TypeOf(testUser{})); !cached {
+		t.Fatal("expected the FieldSet for testUser to be stored in the cache")
+	}
+}
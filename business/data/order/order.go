@@ -5,11 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"github.com/ardanlabs/service/business/sys/validate"
 )
 
+// validStorageField enforces the characters allowed in a storage column
+// reference so a Field can never be used to smuggle SQL into a query.
+var validStorageField = regexp.MustCompile(`^[A-Za-z0-9_\.]+$`)
+
+// validJoinTable enforces the characters allowed in a JOIN table reference,
+// optionally followed by an alias, for the same reason as validStorageField.
+var validJoinTable = regexp.MustCompile(`^[A-Za-z0-9_\.]+(\s+[A-Za-z0-9_]+)?$`)
+
 // Individual directions in the system.
 var (
 	ASC  = Direction{"ASC"}
@@ -39,10 +48,19 @@ func parseDirection(value string) (Direction, error) {
 
 // =============================================================================
 
-// Field represents a field of database being managed.
+// Field represents a field of database being managed. Its name may be a
+// dotted path such as "customer.name" to describe a field that lives on a
+// related entity; AddJoin records the JOIN that storage field depends on.
 type Field struct {
 	name    string
 	storage string
+	joins   []join
+}
+
+// join represents a single JOIN a Field's storage column depends on.
+type join struct {
+	table string
+	on    string
 }
 
 // NewField constructs a new field for the system.
@@ -55,16 +73,51 @@ func NewField(name string) Field {
 // AddStorageField constructs a Field value and checks for potential sql
 // injection issues. If there is an error it will panic.
 func (f *Field) AddStorageField(name string) Field {
+	if !validStorageField.MatchString(name) {
+		panic(fmt.Sprintf("invalid storage field name %q", name))
+	}
+
 	f.storage = name
 
 	return *f
 }
 
+// AddJoin records a JOIN that must be present in a query for this field's
+// storage column to be reachable, for example a related entity's table. A
+// Field may depend on more than one join for multi-hop paths. The table
+// reference is checked the same way AddStorageField checks its column name;
+// on is a boolean expression rather than a bare identifier, so, like the
+// rest of a Field's construction, it is trusted to come from code registering
+// fields rather than from a request.
+func (f *Field) AddJoin(table string, on string) Field {
+	if !validJoinTable.MatchString(table) {
+		panic(fmt.Sprintf("invalid join table %q", table))
+	}
+
+	f.joins = append(f.joins, join{table: table, on: on})
+
+	return *f
+}
+
+// Name returns the query name this field is registered under, allowing
+// other packages (such as filter) to share the same Field across FieldSets.
+func (f Field) Name() string {
+	return f.name
+}
+
+// Storage returns the already sql-injection-checked storage column for this
+// field, allowing other packages (such as filter) to build SQL fragments
+// from a Field without re-validating it.
+func (f Field) Storage() string {
+	return f.storage
+}
+
 // =============================================================================
 
 // FieldSet maintains a set of fields that belong to an entity.
 type FieldSet struct {
-	fields map[string]Field
+	fields       map[string]Field
+	defaultOrder ByList
 }
 
 // NewFieldSet takes a comma delimited set of fields to add to the set.
@@ -91,6 +144,13 @@ func (fs FieldSet) parseField(field string) (Field, error) {
 	return f, nil
 }
 
+// DefaultOrder returns the ByList discovered from the struct's
+// `default_sorts` tag when the set was built with FieldSetFromStruct. It is
+// empty for field sets built with NewFieldSet.
+func (fs FieldSet) DefaultOrder() ByList {
+	return fs.defaultOrder
+}
+
 // =============================================================================
 
 // By represents a field used to order by and direction.
@@ -109,50 +169,125 @@ func NewBy(field Field, direction Direction) By {
 	return by
 }
 
-// Clause returns a sql string with the ordering information.
-func (b By) Clause() (string, error) {
-	return b.field.storage + " " + b.direction.name, nil
+// SQL returns the JOIN fragments this By's field depends on, along with its
+// ORDER BY fragment, so a store layer can splice both into its base query
+// without knowing in advance which fields were selected to order by.
+func (b By) SQL() ([]string, string, error) {
+	joins := make([]string, len(b.field.joins))
+	for i, j := range b.field.joins {
+		joins[i] = "JOIN " + j.table + " ON " + j.on
+	}
+
+	return joins, b.field.storage + " " + b.direction.name, nil
 }
 
 // =============================================================================
 
-// Parse constructs an order.By value by parsing a string in the form
-// of "field,direction" from the request.
-func Parse(r *http.Request, orderingFields FieldSet, defaultOrder By) (By, error) {
-	v := r.URL.Query().Get("orderBy")
+// ByList represents a list of fields to order by, applied in sequence so the
+// later clauses act as tiebreakers for the earlier ones.
+type ByList []By
+
+// NewByList constructs a new ByList value from one or more By values.
+func NewByList(bys ...By) ByList {
+	return ByList(bys)
+}
+
+// SQL returns the deduplicated JOIN fragments required by every By in the
+// list, along with a single ORDER BY fragment combining them in order. When
+// two fields depend on the same join (for example two columns on the same
+// related table) it is only emitted once.
+func (bl ByList) SQL() ([]string, string, error) {
+	var joins []string
+	seen := make(map[string]bool)
 
-	if v == "" {
+	orderClauses := make([]string, len(bl))
+
+	for i, by := range bl {
+		byJoins, clause, err := by.SQL()
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, j := range byJoins {
+			if seen[j] {
+				continue
+			}
+
+			seen[j] = true
+			joins = append(joins, j)
+		}
+
+		orderClauses[i] = clause
+	}
+
+	return joins, strings.Join(orderClauses, ", "), nil
+}
+
+// =============================================================================
+
+// Parse constructs an order.ByList value by parsing one or more repeated
+// "orderBy" query params from the request, each in the form of
+// "field,direction", for example "?orderBy=last_name,ASC&orderBy=first_name,DESC".
+// Repeated params are used instead of a single delimited one because
+// net/url.ParseQuery (since Go 1.17) rejects an unescaped ";" in a query
+// string and Query() silently discards that error, which would make any
+// ";"-separated clause list vanish rather than fail loudly.
+func Parse(r *http.Request, orderingFields FieldSet, defaultOrder ByList) (ByList, error) {
+	clauses := r.URL.Query()["orderBy"]
+
+	if len(clauses) == 0 {
 		return defaultOrder, nil
 	}
 
-	orderParts := strings.Split(v, ",")
+	seen := make(map[string]bool, len(clauses))
+	byList := make(ByList, 0, len(clauses))
 
-	var by By
-	switch len(orderParts) {
-	case 1:
-		field, err := orderingFields.parseField(strings.Trim(orderParts[0], " "))
-		if err != nil {
-			return By{}, validate.NewFieldsError(v, errors.New("parsing fields"))
+	for _, clause := range clauses {
+		clause = strings.Trim(clause, " ")
+		if clause == "" {
+			continue
 		}
 
-		by = NewBy(field, ASC)
+		orderParts := strings.Split(clause, ",")
 
-	case 2:
-		field, err := orderingFields.parseField(strings.Trim(orderParts[0], " "))
-		if err != nil {
-			return By{}, validate.NewFieldsError(v, errors.New("parsing fields"))
+		var by By
+		switch len(orderParts) {
+		case 1:
+			field, err := orderingFields.parseField(strings.Trim(orderParts[0], " "))
+			if err != nil {
+				return nil, validate.NewFieldsError(clause, errors.New("parsing fields"))
+			}
+
+			by = NewBy(field, ASC)
+
+		case 2:
+			field, err := orderingFields.parseField(strings.Trim(orderParts[0], " "))
+			if err != nil {
+				return nil, validate.NewFieldsError(clause, errors.New("parsing fields"))
+			}
+
+			dir, err := parseDirection(strings.Trim(orderParts[1], " "))
+			if err != nil {
+				return nil, validate.NewFieldsError(clause, errors.New("parsing direction"))
+			}
+
+			by = NewBy(field, dir)
+
+		default:
+			return nil, validate.NewFieldsError(clause, errors.New("unknown order field"))
 		}
 
-		dir, err := parseDirection(strings.Trim(orderParts[1], " "))
-		if err != nil {
-			return By{}, validate.NewFieldsError(v, errors.New("parsing direction"))
+		if seen[by.field.name] {
+			return nil, validate.NewFieldsError(clause, fmt.Errorf("field %q specified more than once", by.field.name))
 		}
+		seen[by.field.name] = true
 
-		by = NewBy(field, dir)
+		byList = append(byList, by)
+	}
 
-	default:
-		return By{}, validate.NewFieldsError(v, errors.New("unknown order field"))
+	if len(byList) == 0 {
+		return defaultOrder, nil
 	}
 
-	return by, nil
+	return byList, nil
 }
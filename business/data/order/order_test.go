@@ -0,0 +1,69 @@
+package order
+
+import "testing"
+
+func TestByList_SQL_MultiHopJoin(t *testing.T) {
+	region := NewField("region")
+	region.AddStorageField("r.name")
+	region.AddJoin("customers c", "c.id = orders.customer_id")
+	region.AddJoin("regions r", "r.id = c.region_id")
+
+	by := NewByList(NewBy(region, ASC))
+
+	joins, clause, err := by.SQL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantJoins := []string{
+		"JOIN customers c ON c.id = orders.customer_id",
+		"JOIN regions r ON r.id = c.region_id",
+	}
+	if len(joins) != len(wantJoins) {
+		t.Fatalf("got %d joins, want %d: %v", len(joins), len(wantJoins), joins)
+	}
+	for i, want := range wantJoins {
+		if joins[i] != want {
+			t.Errorf("join[%d] = %q, want %q", i, joins[i], want)
+		}
+	}
+
+	wantClause := "r.name ASC"
+	if clause != wantClause {
+		t.Errorf("clause = %q, want %q", clause, wantClause)
+	}
+}
+
+func TestByList_SQL_DeduplicatesSharedJoin(t *testing.T) {
+	customerName := NewField("customer.name")
+	customerName.AddStorageField("c.name")
+	customerName.AddJoin("customers c", "c.id = orders.customer_id")
+
+	customerEmail := NewField("customer.email")
+	customerEmail.AddStorageField("c.email")
+	customerEmail.AddJoin("customers c", "c.id = orders.customer_id")
+
+	by := NewByList(
+		NewBy(customerName, ASC),
+		NewBy(customerEmail, DESC),
+	)
+
+	joins, clause, err := by.SQL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(joins) != 1 {
+		t.Fatalf("got %d joins, want 1 (deduplicated): %v", len(joins), joins)
+	}
+
+	wantJoin := "JOIN customers c ON c.id = orders.customer_id"
+	if joins[0] != wantJoin {
+		t.Errorf("join = %q, want %q", joins[0], wantJoin)
+	}
+
+	wantClause := "c.name ASC, c.email DESC"
+	if clause != wantClause {
+		t.Errorf("clause = %q, want %q", clause, wantClause)
+	}
+}
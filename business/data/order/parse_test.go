@@ -0,0 +1,92 @@
+package order
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+var testFields = NewFieldSet(
+	func() Field {
+		f := NewField("last_name")
+		f.AddStorageField("last_name")
+		return f
+	}(),
+	func() Field {
+		f := NewField("first_name")
+		f.AddStorageField("first_name")
+		return f
+	}(),
+	func() Field {
+		f := NewField("id")
+		f.AddStorageField("id")
+		return f
+	}(),
+)
+
+func TestParse_RepeatedParams(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?orderBy=last_name,ASC&orderBy=first_name,DESC&orderBy=id,ASC", nil)
+
+	by, err := Parse(r, testFields, ByList{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, clause, err := by.SQL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := "last_name ASC, first_name DESC, id ASC"
+	if clause != want {
+		t.Errorf("clause = %q, want %q", clause, want)
+	}
+}
+
+func TestParse_DefaultsWhenEmpty(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+
+	field, err := testFields.parseField("id")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defaultOrder := NewByList(NewBy(field, ASC))
+
+	by, err := Parse(r, testFields, defaultOrder)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, clause, err := by.SQL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := "id ASC"; clause != want {
+		t.Errorf("clause = %q, want %q", clause, want)
+	}
+}
+
+func TestParse_RejectsDuplicateField(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?orderBy=last_name,ASC&orderBy=last_name,DESC", nil)
+
+	if _, err := Parse(r, testFields, ByList{}); err == nil {
+		t.Fatal("expected an error for a duplicate order field, got none")
+	}
+}
+
+func TestParse_SemicolonIsNotASeparator(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?orderBy=last_name,ASC;first_name,DESC;id,ASC", nil)
+
+	if got := r.URL.Query().Get("orderBy"); got != "" {
+		t.Fatalf("net/url.ParseQuery unexpectedly accepted an unescaped ';': got %q", got)
+	}
+
+	by, err := Parse(r, testFields, ByList{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(by) != 0 {
+		t.Fatalf("expected Parse to fall back to the (empty) default order, got %v", by)
+	}
+}